@@ -0,0 +1,246 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Writer 抽象了生成过程中实际落盘的方式：既可以写入真实文件系统，也可以写入压缩包内部，
+// 使 createDirs 及各节点创建函数无需关心最终写到哪里。
+type Writer interface {
+	MkdirAll(fullPath string, mode os.FileMode) error
+	Chmod(fullPath string, mode os.FileMode) error
+	WriteFile(fullPath string, data []byte, mode os.FileMode) error
+	Symlink(target, fullPath string) error
+	Close() error
+}
+
+// osWriter 是默认的 Writer 实现，直接写入真实文件系统；零值即可使用
+type osWriter struct{}
+
+func (osWriter) MkdirAll(fullPath string, mode os.FileMode) error {
+	return os.MkdirAll(fullPath, mode)
+}
+
+func (osWriter) Chmod(fullPath string, mode os.FileMode) error {
+	return os.Chmod(fullPath, mode)
+}
+
+func (osWriter) WriteFile(fullPath string, data []byte, mode os.FileMode) error {
+	return os.WriteFile(fullPath, data, mode)
+}
+
+func (osWriter) Symlink(target, fullPath string) error {
+	return os.Symlink(target, fullPath)
+}
+
+func (osWriter) Close() error { return nil }
+
+var _ Writer = osWriter{}
+
+// newArchiveWriter 根据压缩包路径的扩展名选择具体的归档实现；basePath 用于计算包内条目的相对路径，
+// 使压缩包内容以用户选择的根目录名称开头。
+func newArchiveWriter(archivePath, basePath string) (Writer, error) {
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".zip"):
+		return newZipArchiveWriter(archivePath, basePath)
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return newTarGzArchiveWriter(archivePath, basePath)
+	default:
+		return nil, fmt.Errorf("不支持的压缩包格式：%s\n\n支持的格式：\n• .zip\n• .tar.gz / .tgz", archivePath)
+	}
+}
+
+// archiveRootName 根据压缩包文件名推导包内顶层目录名（去掉扩展名），
+// 使生成结果在解压后以一个同名目录展开，而不是把所有条目平铺在解压目的地。
+func archiveRootName(archivePath string) string {
+	base := filepath.Base(archivePath)
+	lower := strings.ToLower(base)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(lower, ext) {
+			return base[:len(base)-len(ext)]
+		}
+	}
+	return base
+}
+
+// zipArchiveWriter 把生成过程直接写入一个 zip 压缩包，而不是落到真实文件系统
+type zipArchiveWriter struct {
+	file    *os.File
+	zw      *zip.Writer
+	rootDir string
+	dirSeen map[string]bool
+}
+
+func newZipArchiveWriter(archivePath, basePath string) (*zipArchiveWriter, error) {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法创建压缩包：%s\n错误原因：%v", archivePath, err)
+	}
+	return &zipArchiveWriter{
+		file:    f,
+		zw:      zip.NewWriter(f),
+		rootDir: filepath.Dir(basePath),
+		dirSeen: map[string]bool{},
+	}, nil
+}
+
+// entryName 把本地文件系统路径转换为压缩包内的条目名，统一使用 "/" 分隔
+func (w *zipArchiveWriter) entryName(fullPath string) string {
+	rel, err := filepath.Rel(w.rootDir, fullPath)
+	if err != nil {
+		rel = fullPath
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (w *zipArchiveWriter) ensureDirEntry(name string, mode os.FileMode) error {
+	name = strings.TrimSuffix(name, "/") + "/"
+	if w.dirSeen[name] {
+		return nil
+	}
+	w.dirSeen[name] = true
+	header := &zip.FileHeader{Name: name, Method: zip.Store}
+	header.SetMode(os.ModeDir | mode.Perm())
+	_, err := w.zw.CreateHeader(header)
+	return err
+}
+
+func (w *zipArchiveWriter) MkdirAll(fullPath string, mode os.FileMode) error {
+	return w.ensureDirEntry(w.entryName(fullPath), mode)
+}
+
+// Chmod 压缩包内条目的权限已经在创建时随 header 一并写入，无需二次设置
+func (w *zipArchiveWriter) Chmod(fullPath string, mode os.FileMode) error {
+	return nil
+}
+
+func (w *zipArchiveWriter) WriteFile(fullPath string, data []byte, mode os.FileMode) error {
+	header := &zip.FileHeader{Name: w.entryName(fullPath), Method: zip.Deflate}
+	header.SetMode(mode.Perm())
+	writer, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write(data)
+	return err
+}
+
+// Symlink zip 格式没有原生的符号链接类型，这里沿用 Info-ZIP 的约定：
+// 把链接目标写作条目内容，并在外部属性上标记 ModeSymlink。
+func (w *zipArchiveWriter) Symlink(target, fullPath string) error {
+	header := &zip.FileHeader{Name: w.entryName(fullPath), Method: zip.Store}
+	header.SetMode(os.ModeSymlink | 0777)
+	writer, err := w.zw.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+	_, err = writer.Write([]byte(target))
+	return err
+}
+
+func (w *zipArchiveWriter) Close() error {
+	if err := w.zw.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+var _ Writer = (*zipArchiveWriter)(nil)
+
+// tarGzArchiveWriter 把生成过程直接写入一个 tar.gz 压缩包，而不是落到真实文件系统
+type tarGzArchiveWriter struct {
+	file    *os.File
+	gz      *gzip.Writer
+	tw      *tar.Writer
+	rootDir string
+	dirSeen map[string]bool
+}
+
+func newTarGzArchiveWriter(archivePath, basePath string) (*tarGzArchiveWriter, error) {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法创建压缩包：%s\n错误原因：%v", archivePath, err)
+	}
+	gz := gzip.NewWriter(f)
+	return &tarGzArchiveWriter{
+		file:    f,
+		gz:      gz,
+		tw:      tar.NewWriter(gz),
+		rootDir: filepath.Dir(basePath),
+		dirSeen: map[string]bool{},
+	}, nil
+}
+
+func (w *tarGzArchiveWriter) entryName(fullPath string) string {
+	rel, err := filepath.Rel(w.rootDir, fullPath)
+	if err != nil {
+		rel = fullPath
+	}
+	return filepath.ToSlash(rel)
+}
+
+func (w *tarGzArchiveWriter) ensureDirEntry(name string, mode os.FileMode) error {
+	name = strings.TrimSuffix(name, "/") + "/"
+	if w.dirSeen[name] {
+		return nil
+	}
+	w.dirSeen[name] = true
+	return w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     name,
+		Mode:     int64(mode.Perm()),
+	})
+}
+
+func (w *tarGzArchiveWriter) MkdirAll(fullPath string, mode os.FileMode) error {
+	return w.ensureDirEntry(w.entryName(fullPath), mode)
+}
+
+func (w *tarGzArchiveWriter) Chmod(fullPath string, mode os.FileMode) error {
+	return nil
+}
+
+func (w *tarGzArchiveWriter) WriteFile(fullPath string, data []byte, mode os.FileMode) error {
+	if err := w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     w.entryName(fullPath),
+		Mode:     int64(mode.Perm()),
+		Size:     int64(len(data)),
+	}); err != nil {
+		return err
+	}
+	_, err := w.tw.Write(data)
+	return err
+}
+
+func (w *tarGzArchiveWriter) Symlink(target, fullPath string) error {
+	return w.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     w.entryName(fullPath),
+		Linkname: target,
+		Mode:     0777,
+	})
+}
+
+func (w *tarGzArchiveWriter) Close() error {
+	if err := w.tw.Close(); err != nil {
+		w.gz.Close()
+		w.file.Close()
+		return err
+	}
+	if err := w.gz.Close(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
+
+var _ Writer = (*tarGzArchiveWriter)(nil)
@@ -8,6 +8,7 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"fyne.io/fyne/v2"
@@ -36,51 +37,65 @@ func (m *myTheme) Font(style fyne.TextStyle) fyne.Resource    { return theme.Lig
 func (m *myTheme) Icon(name fyne.ThemeIconName) fyne.Resource { return theme.LightTheme().Icon(name) }
 func (m *myTheme) Size(name fyne.ThemeSizeName) float32       { return theme.LightTheme().Size(name) }
 
-// parseStructureFromFile 解析 JSON 或 YAML 文件为 map[string]interface{}
-func parseStructureFromFile(filePath string) (map[string]interface{}, error) {
+// parseStructureFromFile 解析 JSON 或 YAML 文件为目录结构、变量表与命名规则列表。
+// 配置文件既可以是一个纯粹的目录结构 map（旧格式，保持兼容），
+// 也可以是 { "structure": {...}, "variables": {...}, "rules": [...] } 这种带有顶层元数据的新格式，
+// 此时 variables 中的键值会作为模板渲染时可用的变量，rules 会作为命名规则引擎的初始规则列表。
+func parseStructureFromFile(filePath string) (map[string]interface{}, map[string]interface{}, []NamingRule, error) {
 	// 检查文件是否存在
 	if _, err := os.Stat(filePath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("配置文件不存在：%s\n请确认文件路径是否正确", filePath)
+		return nil, nil, nil, fmt.Errorf("配置文件不存在：%s\n请确认文件路径是否正确", filePath)
 	}
 
 	// 读取文件内容
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return nil, fmt.Errorf("无法读取配置文件：%s\n错误原因：%v\n请检查文件权限是否足够", filePath, err)
+		return nil, nil, nil, fmt.Errorf("无法读取配置文件：%s\n错误原因：%v\n请检查文件权限是否足够", filePath, err)
 	}
 
 	// 检查文件是否为空
 	if len(data) == 0 {
-		return nil, fmt.Errorf("配置文件为空：%s\n请确认文件包含有效的配置内容", filePath)
+		return nil, nil, nil, fmt.Errorf("配置文件为空：%s\n请确认文件包含有效的配置内容", filePath)
 	}
 
-	var structure map[string]interface{}
+	var raw map[string]interface{}
 	// 根据文件后缀选择解析器
 	ext := strings.ToLower(filepath.Ext(filePath))
 	switch ext {
 	case ".json":
-		err = json.Unmarshal(data, &structure)
+		err = json.Unmarshal(data, &raw)
 		if err != nil {
-			return nil, fmt.Errorf("JSON 格式解析失败：%s\n错误详情：%v\n\n请检查：\n• JSON 语法是否正确\n• 括号、引号是否匹配\n• 是否有多余的逗号", filePath, err)
+			return nil, nil, nil, fmt.Errorf("JSON 格式解析失败：%s\n错误详情：%v\n\n请检查：\n• JSON 语法是否正确\n• 括号、引号是否匹配\n• 是否有多余的逗号", filePath, err)
 		}
 	case ".yaml", ".yml":
-		err = yaml.Unmarshal(data, &structure)
+		err = yaml.Unmarshal(data, &raw)
 		if err != nil {
-			return nil, fmt.Errorf("YAML 格式解析失败：%s\n错误详情：%v\n\n请检查：\n• YAML 缩进是否正确（使用空格，不使用制表符）\n• 冒号后是否有空格\n• 特殊字符是否需要引号", filePath, err)
+			return nil, nil, nil, fmt.Errorf("YAML 格式解析失败：%s\n错误详情：%v\n\n请检查：\n• YAML 缩进是否正确（使用空格，不使用制表符）\n• 冒号后是否有空格\n• 特殊字符是否需要引号", filePath, err)
 		}
 	default:
-		return nil, fmt.Errorf("不支持的配置文件格式：%s\n\n支持的格式：\n• .json - JSON 格式\n• .yaml - YAML 格式\n• .yml - YAML 格式\n\n请将文件保存为支持的格式后重试", ext)
+		return nil, nil, nil, fmt.Errorf("不支持的配置文件格式：%s\n\n支持的格式：\n• .json - JSON 格式\n• .yaml - YAML 格式\n• .yml - YAML 格式\n\n请将文件保存为支持的格式后重试", ext)
 	}
 
 	// 检查解析后的结构是否为空
-	if len(structure) == 0 {
-		return nil, fmt.Errorf("配置文件解析后为空：%s\n请确认文件包含有效的目录结构配置", filePath)
+	if len(raw) == 0 {
+		return nil, nil, nil, fmt.Errorf("配置文件解析后为空：%s\n请确认文件包含有效的目录结构配置", filePath)
 	}
 
-	return structure, nil
+	structure := raw
+	var variables map[string]interface{}
+	var rules []NamingRule
+	if top, ok := raw["structure"].(map[string]interface{}); ok {
+		structure = top
+		if vars, ok := raw["variables"].(map[string]interface{}); ok {
+			variables = vars
+		}
+		rules = parseNamingRulesFromAny(raw["rules"])
+	}
+
+	return structure, variables, rules, nil
 }
 
-func createDirs(basePath string, structure map[string]interface{}, enablePrefix bool, prefix string) []string {
+func createDirs(basePath string, structure map[string]interface{}, ctx genContext) []string {
 	var logs []string
 
 	// 检查基础路径是否有效
@@ -89,31 +104,68 @@ func createDirs(basePath string, structure map[string]interface{}, enablePrefix
 		return logs
 	}
 
-	// 检查基础路径是否存在
-	if _, err := os.Stat(basePath); os.IsNotExist(err) {
-		logs = append(logs, fmt.Sprintf("警告：目标路径不存在，将尝试创建：%s\n", basePath))
-		if err := os.MkdirAll(basePath, 0755); err != nil {
-			logs = append(logs, fmt.Sprintf("错误：无法创建目标路径 %s\n原因：%v\n", basePath, err))
-			return logs
+	w := ctx.Writer
+	if w == nil {
+		w = osWriter{}
+	}
+
+	// 检查基础路径是否存在（压缩包写入没有“已存在的真实目录”概念，始终直接确保根条目存在）
+	if _, isFSWriter := w.(osWriter); isFSWriter {
+		if _, err := os.Stat(basePath); os.IsNotExist(err) {
+			logs = append(logs, fmt.Sprintf("警告：目标路径不存在，将尝试创建：%s\n", basePath))
+			if err := w.MkdirAll(basePath, 0755); err != nil {
+				logs = append(logs, fmt.Sprintf("错误：无法创建目标路径 %s\n原因：%v\n", basePath, err))
+				return logs
+			}
+			recordOperation(ctx.Ops, OpMkdirParent, basePath)
+			logs = append(logs, fmt.Sprintf("成功：已创建目标路径 %s\n", basePath))
 		}
-		logs = append(logs, fmt.Sprintf("成功：已创建目标路径 %s\n", basePath))
+	} else if err := w.MkdirAll(basePath, 0755); err != nil {
+		logs = append(logs, fmt.Sprintf("错误：无法创建目标路径 %s\n原因：%v\n", basePath, err))
+		return logs
 	}
 
-	for dir, subDirs := range structure {
+	for index, dir := range sortedKeys(structure) {
+		subDirs := structure[dir]
 		// 验证目录名称
 		if dir == "" {
 			logs = append(logs, "跳过：发现空的目录名称\n")
 			continue
 		}
 
-		// 应用前缀
+		// 带 "type" 字段的节点可能携带 skip 标记或局部前缀覆盖，需要提前解析出来
+		node, isTyped := parseTypedNode(subDirs)
+		if isTyped && node.Skip {
+			logs = append(logs, fmt.Sprintf("跳过（已标记跳过）：\"%s\"\n", dir))
+			continue
+		}
+
+		// 计算最终目录名：节点上的局部前缀覆盖优先于命名规则引擎
 		finalDirName := dir
-		if enablePrefix && prefix != "" {
-			finalDirName = prefix + dir
-			logs = append(logs, fmt.Sprintf("应用前缀：\"%s\" -> \"%s\"\n", dir, finalDirName))
+		switch {
+		case isTyped && node.PrefixOverride != "":
+			finalDirName = node.PrefixOverride + dir
+			logs = append(logs, fmt.Sprintf("应用局部前缀：\"%s\" -> \"%s\"\n", dir, finalDirName))
+		case len(ctx.Rules) > 0:
+			nameCtx := nameContext{
+				Name:       dir,
+				Depth:      ctx.Depth + 1,
+				Path:       joinRelPath(ctx.RelPath, dir),
+				Index:      index,
+				ParentName: ctx.ParentName,
+			}
+			rendered, err := applyNamingRules(ctx.Rules, nameCtx)
+			if err != nil {
+				logs = append(logs, fmt.Sprintf("跳过：%v\n", err))
+				continue
+			}
+			if rendered != dir {
+				logs = append(logs, fmt.Sprintf("应用命名规则：\"%s\" -> \"%s\"\n", dir, rendered))
+			}
+			finalDirName = rendered
 		}
 
-		// 检查目录名称中的非法字符（使用最终的目录名）
+		// 检查目录名称中的非法字符（使用最终的目录名，规则模板渲染之后）
 		if strings.ContainsAny(finalDirName, `<>:"|?*`) {
 			logs = append(logs, fmt.Sprintf("跳过：目录名包含非法字符 \"%s\"\n", finalDirName))
 			continue
@@ -127,7 +179,50 @@ func createDirs(basePath string, structure map[string]interface{}, enablePrefix
 			continue
 		}
 
-		err := os.MkdirAll(fullPath, 0755)
+		childCtx := ctx.child(dir)
+
+		// 带 "type" 字段的节点：文件、软链接或显式声明的目录，各自走独立的创建逻辑
+		if isTyped {
+			switch node.Type {
+			case nodeTypeFile:
+				fileLogs, created := createFileNode(w, fullPath, node, ctx.Vars)
+				logs = append(logs, fileLogs...)
+				if created {
+					recordOperation(ctx.Ops, OpCreateFile, fullPath)
+				}
+				continue
+			case nodeTypeSymlink:
+				symlinkLogs, created := createSymlinkNode(w, fullPath, node)
+				logs = append(logs, symlinkLogs...)
+				if created {
+					recordOperation(ctx.Ops, OpSymlink, fullPath)
+				}
+				continue
+			case nodeTypeDir:
+				dirLogs, created := createDirWithMode(w, fullPath, parseFileMode(node.Mode, 0755))
+				logs = append(logs, dirLogs...)
+				if created {
+					recordOperation(ctx.Ops, OpCreateDir, fullPath)
+				}
+				if node.Children != nil {
+					logs = append(logs, createDirs(fullPath, node.Children, childCtx)...)
+				}
+				continue
+			default:
+				// 未知/拼写错误的 type 取值：明确跳过该节点，不再把节点自身的
+				// type/mode/content 等元数据字段当作子目录去递归创建
+				logs = append(logs, fmt.Sprintf("跳过：未知的 type 取值 \"%s\"，节点 \"%s\" 已忽略\n", node.Type, dir))
+				continue
+			}
+		}
+
+		existedBefore := false
+		if _, isFSWriter := w.(osWriter); isFSWriter {
+			if _, statErr := os.Stat(fullPath); statErr == nil {
+				existedBefore = true
+			}
+		}
+		err := w.MkdirAll(fullPath, 0755)
 		if err != nil {
 			// 详细的错误分析
 			errorMsg := fmt.Sprintf("创建目录失败：%s\n", fullPath)
@@ -138,7 +233,7 @@ func createDirs(basePath string, structure map[string]interface{}, enablePrefix
 				logs = append(logs, fmt.Sprintf("目录已存在：%s\n", fullPath))
 				// 如果目录已存在，继续处理子目录
 				if subDirsMap, ok := subDirs.(map[string]interface{}); ok && subDirsMap != nil {
-					logs = append(logs, createDirs(fullPath, subDirsMap, enablePrefix, prefix)...)
+					logs = append(logs, createDirs(fullPath, subDirsMap, childCtx)...)
 				}
 				continue
 			} else {
@@ -149,11 +244,14 @@ func createDirs(basePath string, structure map[string]interface{}, enablePrefix
 		} else {
 			log.Printf("创建目录：%s\n", fullPath)
 			logs = append(logs, fmt.Sprintf("✓ 成功创建：%s\n", fullPath))
+			if !existedBefore {
+				recordOperation(ctx.Ops, OpCreateDir, fullPath)
+			}
 		}
 
 		// 递归处理子目录
 		if subDirsMap, ok := subDirs.(map[string]interface{}); ok && subDirsMap != nil {
-			logs = append(logs, createDirs(fullPath, subDirsMap, enablePrefix, prefix)...)
+			logs = append(logs, createDirs(fullPath, subDirsMap, childCtx)...)
 		}
 	}
 	return logs
@@ -171,8 +269,8 @@ func main() {
 	// --- 状态变量 ---
 	var targetPath string
 	var loadedDirStructure map[string]interface{}
-	var enablePrefix bool
-	var prefix string
+	var loadedVariables map[string]interface{}
+	var namingRules []NamingRule
 
 	// --- GUI组件 ---
 	title := widget.NewLabel("=== 目录树生成工具 ===")
@@ -182,29 +280,137 @@ func main() {
 	pathLabel := widget.NewLabel("目标路径: 未选择")
 	configLabel := widget.NewLabel("配置文件: 未加载")
 
-	// 前缀功能组件
-	prefixCheck := widget.NewCheck("为所有文件夹添加前缀", nil)
-	prefixEntry := widget.NewEntry()
-	prefixEntry.SetPlaceHolder("输入前缀（例如：C_）")
-	prefixEntry.Disable() // 默认禁用
-
-	// 前缀勾选框事件
-	prefixCheck.OnChanged = func(checked bool) {
-		enablePrefix = checked
-		if checked {
-			prefixEntry.Enable()
-		} else {
-			prefixEntry.Disable()
-			prefixEntry.SetText("")
-			prefix = ""
+	// 命名规则面板：按顺序列出规则，第一条匹配的规则会重写节点的最终目录名
+	rulesBox := container.NewVBox()
+	var rebuildRulesPanel func()
+
+	newRuleRow := func(idx int) fyne.CanvasObject {
+		rule := namingRules[idx]
+
+		depthEntry := widget.NewEntry()
+		depthEntry.SetPlaceHolder("深度(可空)")
+		if rule.Depth > 0 {
+			depthEntry.SetText(strconv.Itoa(rule.Depth))
+		}
+		matchEntry := widget.NewEntry()
+		matchEntry.SetPlaceHolder("匹配(如 docs/*)")
+		matchEntry.SetText(rule.Match)
+		prefixEntry := widget.NewEntry()
+		prefixEntry.SetPlaceHolder("前缀")
+		prefixEntry.SetText(rule.Prefix)
+		suffixEntry := widget.NewEntry()
+		suffixEntry.SetPlaceHolder("后缀")
+		suffixEntry.SetText(rule.Suffix)
+		templateEntry := widget.NewEntry()
+		templateEntry.SetPlaceHolder(`模板，优先于前缀/后缀，如 {{.Index|printf "%02d"}}-{{.Name}}`)
+		templateEntry.SetText(rule.Template)
+
+		syncRule := func(string) {
+			depth, _ := strconv.Atoi(strings.TrimSpace(depthEntry.Text))
+			namingRules[idx] = NamingRule{
+				Depth:    depth,
+				Match:    matchEntry.Text,
+				Prefix:   prefixEntry.Text,
+				Suffix:   suffixEntry.Text,
+				Template: templateEntry.Text,
+			}
 		}
+		depthEntry.OnChanged = syncRule
+		matchEntry.OnChanged = syncRule
+		prefixEntry.OnChanged = syncRule
+		suffixEntry.OnChanged = syncRule
+		templateEntry.OnChanged = syncRule
+
+		upBtn := widget.NewButtonWithIcon("", theme.MoveUpIcon(), func() {
+			if idx == 0 {
+				return
+			}
+			namingRules[idx-1], namingRules[idx] = namingRules[idx], namingRules[idx-1]
+			rebuildRulesPanel()
+		})
+		downBtn := widget.NewButtonWithIcon("", theme.MoveDownIcon(), func() {
+			if idx == len(namingRules)-1 {
+				return
+			}
+			namingRules[idx+1], namingRules[idx] = namingRules[idx], namingRules[idx+1]
+			rebuildRulesPanel()
+		})
+		removeBtn := widget.NewButtonWithIcon("", theme.DeleteIcon(), func() {
+			namingRules = append(namingRules[:idx], namingRules[idx+1:]...)
+			rebuildRulesPanel()
+		})
+
+		return container.NewVBox(
+			container.NewGridWithColumns(2, depthEntry, matchEntry),
+			container.NewGridWithColumns(2, prefixEntry, suffixEntry),
+			templateEntry,
+			container.NewGridWithColumns(3, upBtn, downBtn, removeBtn),
+			widget.NewSeparator(),
+		)
 	}
 
-	// 前缀输入框事件
-	prefixEntry.OnChanged = func(text string) {
-		prefix = text
+	rebuildRulesPanel = func() {
+		rulesBox.RemoveAll()
+		for i := range namingRules {
+			rulesBox.Add(newRuleRow(i))
+		}
+		rulesBox.Refresh()
+	}
+
+	addRuleBtn := widget.NewButton("+ 添加命名规则", func() {
+		namingRules = append(namingRules, NamingRule{})
+		rebuildRulesPanel()
+	})
+
+	// 变量面板：展示配置文件中声明的模板变量，供用户在生成前调整取值
+	variablesLabel := widget.NewLabel("变量设置: 无")
+	variablesBox := container.NewVBox()
+
+	rebuildVariablesPanel := func() {
+		variablesBox.RemoveAll()
+		if len(loadedVariables) == 0 {
+			variablesLabel.SetText("变量设置: 无")
+			variablesBox.Refresh()
+			return
+		}
+		variablesLabel.SetText("变量设置:")
+		for key := range loadedVariables {
+			key := key
+			entry := widget.NewEntry()
+			entry.SetText(fmt.Sprintf("%v", loadedVariables[key]))
+			entry.OnChanged = func(text string) {
+				loadedVariables[key] = text
+			}
+			variablesBox.Add(container.NewBorder(nil, nil, widget.NewLabel(key+":"), nil, entry))
+		}
+		variablesBox.Refresh()
 	}
 
+	// 结构预览树：展示已加载的配置，支持右键编辑单个节点
+	previewTree := widget.NewTree(nil, nil, nil, nil)
+	rebuildPreviewTree := func() {
+		if loadedDirStructure == nil {
+			previewTree.ChildUIDs = nil
+			previewTree.IsBranch = nil
+			previewTree.CreateNode = nil
+			previewTree.UpdateNode = nil
+			previewTree.Refresh()
+			return
+		}
+		childUIDs, isBranch, create, update := buildTreeDataSource(loadedDirStructure, func(uid string, pos fyne.Position) {
+			showNodeEditMenu(myWindow, loadedDirStructure, uid, pos, func() {
+				previewTree.Refresh()
+			})
+		})
+		previewTree.ChildUIDs = childUIDs
+		previewTree.IsBranch = isBranch
+		previewTree.CreateNode = create
+		previewTree.UpdateNode = update
+		previewTree.Refresh()
+	}
+	previewScroll := container.NewScroll(previewTree)
+	previewScroll.SetMinSize(fyne.NewSize(560, 160))
+
 	selectBtn := widget.NewButton("选择目标文件夹", func() {
 		folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
 			if err != nil {
@@ -275,23 +481,36 @@ func main() {
 				return
 			}
 
-			structure, err := parseStructureFromFile(filePath)
+			structure, vars, rules, err := parseStructureFromFile(filePath)
 			if err != nil {
 				// 显示详细的解析错误信息
 				dialog.ShowError(err, myWindow)
 				loadedDirStructure = nil
+				loadedVariables = nil
+				namingRules = nil
+				rebuildVariablesPanel()
+				rebuildPreviewTree()
+				rebuildRulesPanel()
 				configLabel.SetText("配置文件: 加载失败")
 				return
 			}
 
 			// 解析成功
 			loadedDirStructure = structure
+			loadedVariables = vars
+			if loadedVariables == nil {
+				loadedVariables = map[string]interface{}{}
+			}
+			namingRules = rules
+			rebuildVariablesPanel()
+			rebuildPreviewTree()
+			rebuildRulesPanel()
 			fileName := filepath.Base(filePath)
 			configLabel.SetText("配置文件: " + fileName)
 
 			// 显示加载成功信息，包含统计
-			totalDirs := countTotalDirectories(structure)
-			successMsg := fmt.Sprintf("配置文件加载成功！\n\n文件：%s\n预计创建目录数量：%d", fileName, totalDirs)
+			counts := countTotalDirectories(structure)
+			successMsg := fmt.Sprintf("配置文件加载成功！\n\n文件：%s\n预计创建目录：%d 个\n预计创建文件：%d 个\n预计创建软链接：%d 个", fileName, counts.Dirs, counts.Files, counts.Links)
 			dialog.ShowInformation("加载成功", successMsg, myWindow)
 
 		}, myWindow)
@@ -313,31 +532,229 @@ func main() {
 		fileDialog.Show()
 	})
 
+	scanBtn := widget.NewButton("从现有目录导入", func() {
+		folderDialog := dialog.NewFolderOpen(func(uri fyne.ListableURI, err error) {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("选择目录时发生错误：\n%v\n\n请重试或选择其他目录", err), myWindow)
+				return
+			}
+			if uri == nil {
+				return
+			}
+
+			scanPath := uri.Path()
+			if scanPath == "" {
+				dialog.ShowError(fmt.Errorf("获取目录路径失败\n请重新选择"), myWindow)
+				return
+			}
+
+			maxDepthEntry := widget.NewEntry()
+			maxDepthEntry.SetPlaceHolder("0 表示不限制")
+			includeEntry := widget.NewEntry()
+			includeEntry.SetPlaceHolder("例如：*.go,*.md（留空表示不过滤）")
+			excludeEntry := widget.NewEntry()
+			excludeEntry.SetPlaceHolder("例如：.git,node_modules")
+			includeFilesCheck := widget.NewCheck("同时记录文件", nil)
+			stripPrefixCheck := widget.NewCheck("剥离唯一的顶层目录前缀", nil)
+
+			dialog.ShowForm("扫描选项", "开始扫描", "取消", []*widget.FormItem{
+				widget.NewFormItem("最大深度", maxDepthEntry),
+				widget.NewFormItem("包含模式", includeEntry),
+				widget.NewFormItem("排除模式", excludeEntry),
+				widget.NewFormItem("", includeFilesCheck),
+				widget.NewFormItem("", stripPrefixCheck),
+			}, func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+
+				opts := ScanOptions{
+					IncludeFiles:      includeFilesCheck.Checked,
+					StripCommonPrefix: stripPrefixCheck.Checked,
+				}
+				if depth, err := strconv.Atoi(strings.TrimSpace(maxDepthEntry.Text)); err == nil {
+					opts.MaxDepth = depth
+				}
+				if includeEntry.Text != "" {
+					opts.IncludeGlobs = strings.Split(includeEntry.Text, ",")
+				}
+				if excludeEntry.Text != "" {
+					opts.ExcludeGlobs = strings.Split(excludeEntry.Text, ",")
+				}
+
+				scanned, err := scanDirectory(scanPath, opts)
+				if err != nil {
+					dialog.ShowError(err, myWindow)
+					return
+				}
+
+				saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+					if err != nil {
+						dialog.ShowError(fmt.Errorf("保存文件时发生错误：\n%v", err), myWindow)
+						return
+					}
+					if writer == nil {
+						// 用户取消保存
+						return
+					}
+					outPath := writer.URI().Path()
+					writer.Close()
+
+					if err := saveStructureToFile(outPath, scanned); err != nil {
+						dialog.ShowError(err, myWindow)
+						return
+					}
+					counts := countTotalDirectories(scanned)
+					dialog.ShowInformation("导出成功", fmt.Sprintf("已将扫描结果保存到：\n%s\n\n共 %d 个目录、%d 个文件", outPath, counts.Dirs, counts.Files), myWindow)
+				}, myWindow)
+				saveDialog.SetFileName("structure.json")
+				saveDialog.Show()
+			}, myWindow)
+		}, myWindow)
+		folderDialog.Show()
+	})
+
+	saveConfigBtn := widget.NewButton("保存当前配置", func() {
+		if loadedDirStructure == nil {
+			dialog.ShowError(fmt.Errorf("请先加载配置文件"), myWindow)
+			return
+		}
+
+		out := map[string]interface{}{
+			"structure": loadedDirStructure,
+			"variables": loadedVariables,
+			"rules":     namingRulesToAny(namingRules),
+		}
+
+		saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+			if err != nil {
+				dialog.ShowError(fmt.Errorf("保存文件时发生错误：\n%v", err), myWindow)
+				return
+			}
+			if writer == nil {
+				return
+			}
+			outPath := writer.URI().Path()
+			writer.Close()
+
+			if err := saveStructureToFile(outPath, out); err != nil {
+				dialog.ShowError(err, myWindow)
+				return
+			}
+			dialog.ShowInformation("保存成功", fmt.Sprintf("当前结构、变量与命名规则已保存到：\n%s", outPath), myWindow)
+		}, myWindow)
+		saveDialog.SetFileName("structure.json")
+		saveDialog.Show()
+	})
+
 	output := widget.NewMultiLineEntry()
 	output.SetPlaceHolder("生成信息将显示在这里...")
 	output.SetMinRowsVisible(10)
 	output.Wrapping = fyne.TextWrapWord
 	output.Disable()
 
-	createBtn := widget.NewButton("生成目录树", func() {
-		// 验证必要条件
+	dryRunBtn := widget.NewButton("试运行（不写入磁盘）", func() {
 		if targetPath == "" {
-			dialog.ShowError(fmt.Errorf("请先选择目标文件夹\n\n步骤：\n1. 点击 \"选择目标文件夹\" 按钮\n2. 选择要创建目录树的位置\n3. 确认选择"), myWindow)
+			dialog.ShowError(fmt.Errorf("请先选择目标文件夹"), myWindow)
+			return
+		}
+		if loadedDirStructure == nil {
+			dialog.ShowError(fmt.Errorf("请先加载配置文件"), myWindow)
 			return
 		}
+		logs := dryRunPreview(targetPath, loadedDirStructure, genContext{Rules: namingRules})
+		output.Enable()
+		output.SetText("========== 试运行预览（未写入磁盘） ==========\n\n" + strings.Join(logs, ""))
+		output.Disable()
+	})
+
+	archiveCheck := widget.NewCheck("输出到压缩包（.zip / .tar.gz）而非文件夹", nil)
+
+	// renderGenerationResult 汇总一次生成（无论落地文件夹还是压缩包）的结果并展示给用户
+	renderGenerationResult := func(logMessages []string) {
+		allLogs := strings.Join(logMessages, "")
+		output.SetText(output.Text + allLogs)
 
+		successCount := strings.Count(allLogs, "✓ 成功创建：")
+		errorCount := strings.Count(allLogs, "错误：") + strings.Count(allLogs, "跳过：")
+
+		summary := fmt.Sprintf("\n========== 生成完成 ==========\n成功创建：%d 个目录\n", successCount)
+		if errorCount > 0 {
+			summary += fmt.Sprintf("跳过/失败：%d 个目录\n", errorCount)
+		}
+		summary += "=============================\n"
+		output.SetText(output.Text + summary)
+		output.Disable()
+
+		if errorCount == 0 {
+			dialog.ShowInformation("生成成功", fmt.Sprintf("目录树已成功生成！\n\n共创建了 %d 个目录", successCount), myWindow)
+		} else {
+			dialog.ShowInformation("生成完成", fmt.Sprintf("目录树生成完成！\n\n成功：%d 个目录\n跳过/失败：%d 个目录\n\n请查看详细信息了解具体情况", successCount, errorCount), myWindow)
+		}
+	}
+
+	createBtn := widget.NewButton("生成目录树", func() {
 		if loadedDirStructure == nil {
 			dialog.ShowError(fmt.Errorf("请先加载配置文件\n\n步骤：\n1. 点击 \"加载配置文件\" 按钮\n2. 选择 JSON 或 YAML 格式的配置文件\n3. 确认文件加载成功"), myWindow)
 			return
 		}
 
-		// 最终确认
-		prefixInfo := ""
-		if enablePrefix && prefix != "" {
-			prefixInfo = fmt.Sprintf("\n前缀设置：为所有文件夹添加前缀 \"%s\"", prefix)
+		rulesInfo := ""
+		if len(namingRules) > 0 {
+			rulesInfo = fmt.Sprintf("\n命名规则：已配置 %d 条", len(namingRules))
 		}
+		counts := countTotalDirectories(loadedDirStructure)
 
-		confirmMsg := fmt.Sprintf("即将在以下位置创建目录树：\n%s\n\n预计创建 %d 个目录%s\n\n是否继续？", targetPath, countTotalDirectories(loadedDirStructure), prefixInfo)
+		if archiveCheck.Checked {
+			saveDialog := dialog.NewFileSave(func(writer fyne.URIWriteCloser, err error) {
+				if err != nil {
+					dialog.ShowError(fmt.Errorf("选择压缩包保存位置时发生错误：\n%v", err), myWindow)
+					return
+				}
+				if writer == nil {
+					// 用户取消保存
+					return
+				}
+				archivePath := writer.URI().Path()
+				writer.Close()
+
+				confirmMsg := fmt.Sprintf("即将生成压缩包：\n%s\n\n预计创建 %d 个目录、%d 个文件、%d 个软链接%s\n\n是否继续？", archivePath, counts.Dirs, counts.Files, counts.Links, rulesInfo)
+				dialog.ShowConfirm("确认生成", confirmMsg, func(confirmed bool) {
+					if !confirmed {
+						return
+					}
+
+					aw, err := newArchiveWriter(archivePath, archiveRootName(archivePath))
+					if err != nil {
+						dialog.ShowError(err, myWindow)
+						return
+					}
+
+					output.Enable()
+					output.SetText("开始生成压缩包...\n\n")
+					if len(namingRules) > 0 {
+						output.SetText(output.Text + fmt.Sprintf("命名规则：已配置 %d 条\n\n", len(namingRules)))
+					}
+
+					logMessages := createDirs(archiveRootName(archivePath), loadedDirStructure, genContext{Rules: namingRules, Vars: loadedVariables, Writer: aw})
+					if err := aw.Close(); err != nil {
+						output.SetText(output.Text + fmt.Sprintf("警告：压缩包未能正常关闭：%v\n", err))
+					}
+					renderGenerationResult(logMessages)
+				}, myWindow)
+			}, myWindow)
+			saveDialog.SetFileName("structure.zip")
+			saveDialog.SetFilter(storage.NewExtensionFileFilter([]string{".zip", ".tar.gz", ".tgz"}))
+			saveDialog.Show()
+			return
+		}
+
+		if targetPath == "" {
+			dialog.ShowError(fmt.Errorf("请先选择目标文件夹\n\n步骤：\n1. 点击 \"选择目标文件夹\" 按钮\n2. 选择要创建目录树的位置\n3. 确认选择"), myWindow)
+			return
+		}
+
+		confirmMsg := fmt.Sprintf("即将在以下位置创建目录树：\n%s\n\n预计创建 %d 个目录、%d 个文件、%d 个软链接%s\n\n是否继续？", targetPath, counts.Dirs, counts.Files, counts.Links, rulesInfo)
 		confirmDialog := dialog.NewConfirm("确认创建", confirmMsg, func(confirmed bool) {
 			if !confirmed {
 				return
@@ -346,36 +763,54 @@ func main() {
 			output.Enable()
 			output.SetText("开始生成目录树...\n\n")
 
-			// 显示前缀设置信息
-			if enablePrefix && prefix != "" {
-				output.SetText(output.Text + fmt.Sprintf("前缀设置：为所有文件夹添加前缀 \"%s\"\n\n", prefix))
+			// 显示命名规则信息
+			if len(namingRules) > 0 {
+				output.SetText(output.Text + fmt.Sprintf("命名规则：已配置 %d 条\n\n", len(namingRules)))
 			}
 
-			logMessages := createDirs(targetPath, loadedDirStructure, enablePrefix, prefix)
-			allLogs := strings.Join(logMessages, "")
-			output.SetText(output.Text + allLogs)
+			var ops []Operation
+			logMessages := createDirs(targetPath, loadedDirStructure, genContext{Rules: namingRules, Vars: loadedVariables, Ops: &ops})
+			if err := writeTransactionLog(targetPath, ops); err != nil {
+				output.SetText(output.Text + fmt.Sprintf("警告：%v\n", err))
+			}
+			renderGenerationResult(logMessages)
+		}, myWindow)
 
-			// 统计结果
-			successCount := strings.Count(allLogs, "✓ 成功创建")
-			errorCount := strings.Count(allLogs, "错误：") + strings.Count(allLogs, "跳过：")
+		confirmDialog.Show()
+	})
 
-			summary := fmt.Sprintf("\n========== 生成完成 ==========\n成功创建：%d 个目录\n", successCount)
-			if errorCount > 0 {
-				summary += fmt.Sprintf("跳过/失败：%d 个目录\n", errorCount)
-			}
-			summary += "=============================\n"
+	undoBtn := widget.NewButton("撤销上次生成", func() {
+		if targetPath == "" {
+			dialog.ShowError(fmt.Errorf("请先选择目标文件夹"), myWindow)
+			return
+		}
 
-			output.SetText(output.Text + summary)
-			output.Disable()
+		ops, err := loadTransactionLog(targetPath)
+		if err != nil {
+			dialog.ShowError(err, myWindow)
+			return
+		}
 
-			if errorCount == 0 {
-				dialog.ShowInformation("生成成功", fmt.Sprintf("目录树已成功生成！\n\n共创建了 %d 个目录", successCount), myWindow)
-			} else {
-				dialog.ShowInformation("生成完成", fmt.Sprintf("目录树生成完成！\n\n成功：%d 个目录\n跳过/失败：%d 个目录\n\n请查看详细信息了解具体情况", successCount, errorCount), myWindow)
+		toDelete, skipped := previewUndo(ops)
+		if len(toDelete) == 0 {
+			dialog.ShowInformation("无可撤销内容", "事务日志中的路径均已不满足安全撤销条件，未执行任何删除", myWindow)
+			return
+		}
+
+		previewMsg := fmt.Sprintf("将删除以下 %d 个由上次生成创建的路径：\n\n%s", len(toDelete), strings.Join(toDelete, "\n"))
+		if len(skipped) > 0 {
+			previewMsg += fmt.Sprintf("\n\n以下 %d 个路径因状态已变化，将被跳过：\n\n%s", len(skipped), strings.Join(skipped, "\n"))
+		}
+
+		dialog.ShowConfirm("确认撤销", previewMsg, func(confirmed bool) {
+			if !confirmed {
+				return
 			}
+			logs := undoTransaction(targetPath, ops)
+			output.Enable()
+			output.SetText("========== 撤销结果 ==========\n\n" + strings.Join(logs, ""))
+			output.Disable()
 		}, myWindow)
-
-		confirmDialog.Show()
 	})
 
 	// 布局
@@ -384,14 +819,25 @@ func main() {
 		pathLabel,
 		configLabel,
 		widget.NewSeparator(),
-		// 前缀功能区域
-		widget.NewLabel("前缀设置:"),
-		prefixCheck,
-		container.NewBorder(nil, nil, widget.NewLabel("前缀:"), nil, prefixEntry),
+		// 命名规则区域：按顺序匹配，重写节点最终目录名
+		widget.NewLabel("命名规则:"),
+		rulesBox,
+		addRuleBtn,
+		widget.NewSeparator(),
+		// 变量面板区域
+		variablesLabel,
+		variablesBox,
 		widget.NewSeparator(),
 		container.NewGridWithColumns(2, selectBtn, loadConfigBtn),
+		container.NewGridWithColumns(2, scanBtn, saveConfigBtn),
 		widget.NewSeparator(),
-		createBtn,
+		// 结构预览区域：加载配置后展示可折叠的文件树，右键节点可编辑
+		widget.NewLabel("结构预览（右键节点可编辑）:"),
+		previewScroll,
+		widget.NewSeparator(),
+		archiveCheck,
+		container.NewGridWithColumns(2, dryRunBtn, createBtn),
+		undoBtn,
 		widget.NewSeparator(),
 		widget.NewLabel("生成信息:"),
 	)
@@ -407,15 +853,3 @@ func main() {
 	myWindow.SetContent(content)
 	myWindow.ShowAndRun()
 }
-
-// 辅助函数：计算总目录数量
-func countTotalDirectories(structure map[string]interface{}) int {
-	count := 0
-	for _, subDirs := range structure {
-		count++
-		if subDirsMap, ok := subDirs.(map[string]interface{}); ok && subDirsMap != nil {
-			count += countTotalDirectories(subDirsMap)
-		}
-	}
-	return count
-}
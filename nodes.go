@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/template"
+)
+
+// 节点类型常量，用于标识结构配置中每个条目的具体种类
+const (
+	nodeTypeDir     = "dir"
+	nodeTypeFile    = "file"
+	nodeTypeSymlink = "symlink"
+)
+
+// nodeCounts 记录一次结构统计中目录、文件、软链接各自的数量
+type nodeCounts struct {
+	Dirs  int
+	Files int
+	Links int
+}
+
+// total 返回三类节点的总数
+func (c nodeCounts) total() int {
+	return c.Dirs + c.Files + c.Links
+}
+
+// typedNode 是形如 { "type": "file"/"dir"/"symlink", ... } 的带类型节点的解析结果
+type typedNode struct {
+	Type           string
+	Mode           string
+	Content        string
+	Template       string
+	Target         string
+	Children       map[string]interface{}
+	Skip           bool
+	PrefixOverride string
+}
+
+// parseTypedNode 判断 raw 是否为带 "type" 字段的节点，若是则解析出各字段；
+// 不带 "type" 字段的普通 map（或 nil）按旧版的纯目录格式处理，返回 false。
+func parseTypedNode(raw interface{}) (typedNode, bool) {
+	m, ok := raw.(map[string]interface{})
+	if !ok {
+		return typedNode{}, false
+	}
+	typeVal, ok := m["type"].(string)
+	if !ok {
+		return typedNode{}, false
+	}
+
+	node := typedNode{Type: typeVal}
+	if mode, ok := m["mode"].(string); ok {
+		node.Mode = mode
+	}
+	if content, ok := m["content"].(string); ok {
+		node.Content = content
+	}
+	if tmpl, ok := m["template"].(string); ok {
+		node.Template = tmpl
+	}
+	if target, ok := m["target"].(string); ok {
+		node.Target = target
+	}
+	if children, ok := m["children"].(map[string]interface{}); ok {
+		node.Children = children
+	}
+	if skip, ok := m["skip"].(bool); ok {
+		node.Skip = skip
+	}
+	if prefixOverride, ok := m["prefix"].(string); ok {
+		node.PrefixOverride = prefixOverride
+	}
+	return node, true
+}
+
+// parseFileMode 解析形如 "0644"、"0700" 的八进制权限字符串，解析失败或为空时返回 defaultMode
+func parseFileMode(modeStr string, defaultMode os.FileMode) os.FileMode {
+	if modeStr == "" {
+		return defaultMode
+	}
+	val, err := strconv.ParseUint(modeStr, 8, 32)
+	if err != nil {
+		return defaultMode
+	}
+	return os.FileMode(val)
+}
+
+// renderTemplate 使用 text/template 将 body 中的占位符替换为 vars 中的值
+func renderTemplate(name, body string, vars map[string]interface{}) (string, error) {
+	tmpl, err := template.New(name).Parse(body)
+	if err != nil {
+		return "", fmt.Errorf("模板解析失败：%s\n错误详情：%v", name, err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", fmt.Errorf("模板渲染失败：%s\n错误详情：%v", name, err)
+	}
+	return buf.String(), nil
+}
+
+// resolveFileContent 计算文件节点最终写入的内容：优先加载 template 指向的模板文件，
+// 其次使用内联 content，二者都缺省时写入空文件。
+func resolveFileContent(node typedNode, vars map[string]interface{}) (string, error) {
+	if node.Template != "" {
+		data, err := os.ReadFile(node.Template)
+		if err != nil {
+			return "", fmt.Errorf("无法读取模板文件：%s\n错误原因：%v", node.Template, err)
+		}
+		return renderTemplate(filepath.Base(node.Template), string(data), vars)
+	}
+	if node.Content != "" {
+		return renderTemplate("content", node.Content, vars)
+	}
+	return "", nil
+}
+
+// createFileNode 根据文件节点描述通过 w 写入文件内容；created 表示是否确实写入了新文件
+func createFileNode(w Writer, fullPath string, node typedNode, vars map[string]interface{}) (logs []string, created bool) {
+	content, err := resolveFileContent(node, vars)
+	if err != nil {
+		logs = append(logs, fmt.Sprintf("跳过：%v\n", err))
+		return logs, false
+	}
+	mode := parseFileMode(node.Mode, 0644)
+	if err := w.WriteFile(fullPath, []byte(content), mode); err != nil {
+		logs = append(logs, fmt.Sprintf("错误：创建文件失败：%s\n原因：%v\n", fullPath, err))
+		return logs, false
+	}
+	logs = append(logs, fmt.Sprintf("✓ 成功创建文件：%s\n", fullPath))
+	return logs, true
+}
+
+// createSymlinkNode 根据软链接节点描述通过 w 创建符号链接；created 表示是否确实创建了新链接
+func createSymlinkNode(w Writer, fullPath string, node typedNode) (logs []string, created bool) {
+	if node.Target == "" {
+		logs = append(logs, fmt.Sprintf("跳过：软链接 \"%s\" 缺少 target\n", fullPath))
+		return logs, false
+	}
+	if err := w.Symlink(node.Target, fullPath); err != nil {
+		logs = append(logs, fmt.Sprintf("错误：创建软链接失败：%s -> %s\n原因：%v\n", fullPath, node.Target, err))
+		return logs, false
+	}
+	logs = append(logs, fmt.Sprintf("✓ 成功创建软链接：%s -> %s\n", fullPath, node.Target))
+	return logs, true
+}
+
+// createDirWithMode 通过 w 创建目录并按 mode 设置权限（真实文件系统下 MkdirAll 会受 umask 影响，
+// 额外 Chmod 一次以保证权限准确；压缩包写入时 Chmod 为空操作）。
+// created 表示该目录此前不存在、确实由本次调用创建——仅对真实文件系统有意义，压缩包写入恒为 true。
+func createDirWithMode(w Writer, fullPath string, mode os.FileMode) (logs []string, created bool) {
+	existedBefore := false
+	if _, ok := w.(osWriter); ok {
+		if _, err := os.Stat(fullPath); err == nil {
+			existedBefore = true
+		}
+	}
+	if err := w.MkdirAll(fullPath, mode); err != nil {
+		logs = append(logs, fmt.Sprintf("创建目录失败：%s\n原因：%v\n", fullPath, err))
+		return logs, false
+	}
+	if err := w.Chmod(fullPath, mode); err != nil {
+		logs = append(logs, fmt.Sprintf("警告：无法设置目录权限：%s\n原因：%v\n", fullPath, err))
+	}
+	logs = append(logs, fmt.Sprintf("✓ 成功创建：%s\n", fullPath))
+	return logs, !existedBefore
+}
+
+// countTotalDirectories 统计结构中目录、文件、软链接的数量，用于生成前的预估提示
+func countTotalDirectories(structure map[string]interface{}) nodeCounts {
+	var counts nodeCounts
+	for _, raw := range structure {
+		if node, ok := parseTypedNode(raw); ok {
+			if node.Skip {
+				continue
+			}
+			switch node.Type {
+			case nodeTypeFile:
+				counts.Files++
+			case nodeTypeSymlink:
+				counts.Links++
+			case nodeTypeDir:
+				counts.Dirs++
+				if node.Children != nil {
+					sub := countTotalDirectories(node.Children)
+					counts.Dirs += sub.Dirs
+					counts.Files += sub.Files
+					counts.Links += sub.Links
+				}
+			default:
+				// 未知 type：createDirs 中会跳过该节点，这里不计入任何统计，
+				// 避免预估数量与实际生成结果不一致
+			}
+			continue
+		}
+
+		counts.Dirs++
+		if subDirsMap, ok := raw.(map[string]interface{}); ok && subDirsMap != nil {
+			sub := countTotalDirectories(subDirsMap)
+			counts.Dirs += sub.Dirs
+			counts.Files += sub.Files
+			counts.Links += sub.Links
+		}
+	}
+	return counts
+}
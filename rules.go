@@ -0,0 +1,178 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"text/template"
+)
+
+// NamingRule 描述一条重命名规则，规则按列表顺序尝试，第一条命中的规则会重写节点最终的目录名。
+type NamingRule struct {
+	Depth    int    // 大于 0 时，只对处于该深度（从 1 开始计）的节点生效；0 表示不限制深度
+	Match    string // 针对节点 Path 的 glob 匹配模式（如 "docs/*"）；为空表示不限制
+	Prefix   string
+	Suffix   string
+	Template string // 形如 "{{.Index | printf \"%02d\"}}-{{.Name}}"；非空时优先于 Prefix/Suffix
+}
+
+// nameContext 是命名规则求值与模板渲染时可用的上下文
+type nameContext struct {
+	Name       string
+	Depth      int
+	Path       string
+	Index      int
+	ParentName string
+}
+
+// genContext 携带一次生成/预览过程中不随单个节点变化的状态：规则列表、模板变量，
+// 以及递归下降时逐层累积的深度、父节点名、相对路径。
+type genContext struct {
+	Rules      []NamingRule
+	Vars       map[string]interface{}
+	Depth      int
+	ParentName string
+	RelPath    string
+	Ops        *[]Operation // 非 nil 时，createDirs 会把每次成功的写入追加到这里，用于后续生成事务日志
+	Writer     Writer       // 实际执行写入的目标；为 nil 时 createDirs 会退化为直接写入真实文件系统
+}
+
+// child 基于当前层级信息，推导出下一层递归所需的 genContext
+func (ctx genContext) child(dirName string) genContext {
+	return genContext{
+		Rules:      ctx.Rules,
+		Vars:       ctx.Vars,
+		Depth:      ctx.Depth + 1,
+		ParentName: dirName,
+		RelPath:    joinRelPath(ctx.RelPath, dirName),
+		Ops:        ctx.Ops,
+		Writer:     ctx.Writer,
+	}
+}
+
+// joinRelPath 把父相对路径与子名称拼接成以 "/" 分隔的相对路径，供规则的 Match 字段匹配
+func joinRelPath(parentPath, name string) string {
+	if parentPath == "" {
+		return name
+	}
+	return parentPath + "/" + name
+}
+
+// ruleMatches 判断一条规则是否对给定上下文生效
+func ruleMatches(rule NamingRule, ctx nameContext) bool {
+	if rule.Depth > 0 && rule.Depth != ctx.Depth {
+		return false
+	}
+	if rule.Match != "" {
+		matched, err := filepath.Match(rule.Match, ctx.Path)
+		if err != nil || !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// applyNamingRules 依次尝试规则列表，返回第一条命中规则重写后的名称；没有规则命中时返回原名
+func applyNamingRules(rules []NamingRule, ctx nameContext) (string, error) {
+	for _, rule := range rules {
+		if !ruleMatches(rule, ctx) {
+			continue
+		}
+		if rule.Template != "" {
+			return renderNameTemplate(rule.Template, ctx)
+		}
+		return rule.Prefix + ctx.Name + rule.Suffix, nil
+	}
+	return ctx.Name, nil
+}
+
+// renderNameTemplate 用 text/template 渲染命名模板，上下文字段与 nameContext 一致
+func renderNameTemplate(tmplText string, ctx nameContext) (string, error) {
+	tmpl, err := template.New("naming-rule").Parse(tmplText)
+	if err != nil {
+		return "", fmt.Errorf("命名规则模板解析失败：%v", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, ctx); err != nil {
+		return "", fmt.Errorf("命名规则模板渲染失败：%v", err)
+	}
+	return buf.String(), nil
+}
+
+// sortedKeys 返回 map 键的字典序排列，用于为同级节点提供稳定的 Index
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// parseNamingRulesFromAny 把 JSON/YAML 反序列化出的 []interface{} 转换为 NamingRule 列表，
+// 用于从配置文件顶层的 "rules" 字段加载规则。
+func parseNamingRulesFromAny(raw interface{}) []NamingRule {
+	list, ok := raw.([]interface{})
+	if !ok {
+		return nil
+	}
+	var rules []NamingRule
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		var rule NamingRule
+		switch depth := m["depth"].(type) {
+		case float64:
+			rule.Depth = int(depth)
+		case int:
+			rule.Depth = depth
+		case string:
+			if v, err := strconv.Atoi(depth); err == nil {
+				rule.Depth = v
+			}
+		}
+		if match, ok := m["match"].(string); ok {
+			rule.Match = match
+		}
+		if prefix, ok := m["prefix"].(string); ok {
+			rule.Prefix = prefix
+		}
+		if suffix, ok := m["suffix"].(string); ok {
+			rule.Suffix = suffix
+		}
+		if tmpl, ok := m["template"].(string); ok {
+			rule.Template = tmpl
+		}
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// namingRulesToAny 把规则列表转换回可被 json/yaml 序列化的通用结构，便于随结构一起持久化
+func namingRulesToAny(rules []NamingRule) []interface{} {
+	out := make([]interface{}, 0, len(rules))
+	for _, rule := range rules {
+		m := map[string]interface{}{}
+		if rule.Depth > 0 {
+			m["depth"] = rule.Depth
+		}
+		if rule.Match != "" {
+			m["match"] = rule.Match
+		}
+		if rule.Prefix != "" {
+			m["prefix"] = rule.Prefix
+		}
+		if rule.Suffix != "" {
+			m["suffix"] = rule.Suffix
+		}
+		if rule.Template != "" {
+			m["template"] = rule.Template
+		}
+		out = append(out, m)
+	}
+	return out
+}
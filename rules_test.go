@@ -0,0 +1,84 @@
+package main
+
+import "testing"
+
+func TestRuleMatchesDepth(t *testing.T) {
+	rule := NamingRule{Depth: 2}
+	if ruleMatches(rule, nameContext{Depth: 1}) {
+		t.Fatal("规则要求深度 2，不应匹配深度 1 的节点")
+	}
+	if !ruleMatches(rule, nameContext{Depth: 2}) {
+		t.Fatal("规则要求深度 2，应当匹配深度 2 的节点")
+	}
+}
+
+func TestRuleMatchesGlob(t *testing.T) {
+	rule := NamingRule{Match: "docs/*"}
+	if !ruleMatches(rule, nameContext{Path: "docs/readme"}) {
+		t.Fatal("docs/* 应当匹配 docs/readme")
+	}
+	if ruleMatches(rule, nameContext{Path: "src/main"}) {
+		t.Fatal("docs/* 不应匹配 src/main")
+	}
+}
+
+func TestRuleMatchesBadGlob(t *testing.T) {
+	rule := NamingRule{Match: "["}
+	if ruleMatches(rule, nameContext{Path: "anything"}) {
+		t.Fatal("非法的 glob 模式应当视为不匹配，而不是报错中断")
+	}
+}
+
+func TestApplyNamingRulesPrefixSuffix(t *testing.T) {
+	rules := []NamingRule{{Depth: 1, Prefix: "A_", Suffix: "_Z"}}
+	got, err := applyNamingRules(rules, nameContext{Name: "pkg", Depth: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "A_pkg_Z" {
+		t.Fatalf("got %q, want %q", got, "A_pkg_Z")
+	}
+}
+
+func TestApplyNamingRulesTemplate(t *testing.T) {
+	rules := []NamingRule{{Template: `{{.Index}}-{{.Name}}`}}
+	got, err := applyNamingRules(rules, nameContext{Name: "pkg", Index: 3})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "3-pkg" {
+		t.Fatalf("got %q, want %q", got, "3-pkg")
+	}
+}
+
+func TestApplyNamingRulesNoMatchReturnsOriginalName(t *testing.T) {
+	rules := []NamingRule{{Match: "docs/*"}}
+	got, err := applyNamingRules(rules, nameContext{Name: "pkg", Path: "src/pkg"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "pkg" {
+		t.Fatalf("没有规则命中时应当原样返回名称，got %q", got)
+	}
+}
+
+func TestApplyNamingRulesFirstMatchWins(t *testing.T) {
+	rules := []NamingRule{
+		{Match: "src/*", Prefix: "S_"},
+		{Prefix: "FALLBACK_"},
+	}
+	got, err := applyNamingRules(rules, nameContext{Name: "pkg", Path: "src/pkg"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "S_pkg" {
+		t.Fatalf("应当采用第一条命中的规则，got %q", got)
+	}
+}
+
+func TestApplyNamingRulesBadTemplate(t *testing.T) {
+	rules := []NamingRule{{Template: `{{.Name`}}
+	if _, err := applyNamingRules(rules, nameContext{Name: "pkg"}); err == nil {
+		t.Fatal("模板解析失败时应当返回 error")
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScanOptions 控制 scanDirectory 扫描一个已存在目录时的行为
+type ScanOptions struct {
+	MaxDepth          int      // 最大扫描深度，0 表示不限制
+	IncludeGlobs      []string // 为空表示不做 include 过滤，否则只保留文件名匹配其中任一模式的文件；目录不受此过滤
+	ExcludeGlobs      []string // 匹配其中任一模式的条目会被排除
+	IncludeFiles      bool     // 是否把文件也记录进结构（默认只记录目录）
+	StripCommonPrefix bool     // 当根目录下只有唯一一级目录时，是否剥离这层公共前缀
+}
+
+// scanDirectory 遍历 basePath 下的目录树，生成与 parseStructureFromFile 可互相识别的结构 map，
+// 以便扫描结果能够原样保存、编辑后再用于生成（扫描 -> 编辑 -> 生成 的往返流程）。
+func scanDirectory(basePath string, opts ScanOptions) (map[string]interface{}, error) {
+	info, err := os.Stat(basePath)
+	if err != nil {
+		return nil, fmt.Errorf("无法访问目录：%s\n错误原因：%v", basePath, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("指定路径不是目录：%s", basePath)
+	}
+
+	root := map[string]interface{}{}
+	err = filepath.Walk(basePath, func(path string, fi os.FileInfo, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if path == basePath {
+			return nil
+		}
+
+		rel, err := filepath.Rel(basePath, path)
+		if err != nil {
+			return err
+		}
+		relParts := strings.Split(filepath.ToSlash(rel), "/")
+
+		if opts.MaxDepth > 0 && len(relParts) > opts.MaxDepth {
+			if fi.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fi.IsDir() {
+			// IncludeGlobs 只用于筛选文件条目，目录本身始终继续遍历，
+			// 否则目录名不匹配 include 规则会连带把其下匹配的文件一并剪掉
+			if !matchesGlobs(fi.Name(), nil, opts.ExcludeGlobs) {
+				return filepath.SkipDir
+			}
+			insertScanNode(root, relParts, typedNodeMap(nodeTypeDir, fi.Mode(), map[string]interface{}{}))
+			return nil
+		}
+		if !matchesGlobs(fi.Name(), opts.IncludeGlobs, opts.ExcludeGlobs) {
+			return nil
+		}
+		if !opts.IncludeFiles {
+			return nil
+		}
+		insertScanNode(root, relParts, typedNodeMap(nodeTypeFile, fi.Mode(), nil))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("扫描目录失败：%s\n错误原因：%v", basePath, err)
+	}
+
+	if opts.StripCommonPrefix {
+		root = stripCommonPrefix(root)
+	}
+	return root, nil
+}
+
+// insertScanNode 把扫描到的一个条目按其相对路径各级名称插入到结构树中；
+// 由于 filepath.Walk 保证父目录先于其子项被访问，途中用到的父级目录节点此时必然已经存在。
+func insertScanNode(root map[string]interface{}, relParts []string, leaf map[string]interface{}) {
+	current := root
+	for i, part := range relParts {
+		if i == len(relParts)-1 {
+			current[part] = leaf
+			return
+		}
+		child, ok := current[part].(map[string]interface{})
+		if !ok {
+			// 兜底：理论上不会走到这里，但仍避免因顺序异常导致 panic
+			child = typedNodeMap(nodeTypeDir, os.ModeDir|0755, map[string]interface{}{})
+			current[part] = child
+		}
+		children, _ := child["children"].(map[string]interface{})
+		current = children
+	}
+}
+
+// typedNodeMap 构造一个与 parseTypedNode 对应的带类型节点 map
+func typedNodeMap(nodeType string, mode os.FileMode, children map[string]interface{}) map[string]interface{} {
+	node := map[string]interface{}{
+		"type": nodeType,
+		"mode": fmt.Sprintf("0%o", mode.Perm()),
+	}
+	if nodeType == nodeTypeDir {
+		if children == nil {
+			children = map[string]interface{}{}
+		}
+		node["children"] = children
+	}
+	return node
+}
+
+// matchesGlobs 判断文件名是否应当被收录：先看是否命中排除规则，再看是否命中包含规则
+func matchesGlobs(name string, include, exclude []string) bool {
+	for _, pattern := range exclude {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return false
+		}
+	}
+	if len(include) == 0 {
+		return true
+	}
+	for _, pattern := range include {
+		if matched, _ := filepath.Match(pattern, name); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// stripCommonPrefix 当根结构下只有唯一一级目录节点时，剥离这层公共前缀，直接返回其子节点
+func stripCommonPrefix(root map[string]interface{}) map[string]interface{} {
+	if len(root) != 1 {
+		return root
+	}
+	for _, raw := range root {
+		node, ok := parseTypedNode(raw)
+		if ok && node.Type == nodeTypeDir && node.Children != nil {
+			return node.Children
+		}
+	}
+	return root
+}
+
+// saveStructureToFile 把结构 map 按文件后缀编码为 JSON 或 YAML 并写入 filePath
+func saveStructureToFile(filePath string, structure map[string]interface{}) error {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	var data []byte
+	var err error
+	switch ext {
+	case ".json":
+		data, err = json.MarshalIndent(structure, "", "  ")
+	case ".yaml", ".yml":
+		data, err = yaml.Marshal(structure)
+	default:
+		return fmt.Errorf("不支持的导出格式：%s\n\n支持的格式：\n• .json - JSON 格式\n• .yaml - YAML 格式\n• .yml - YAML 格式", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("结构序列化失败：%v", err)
+	}
+	if err := os.WriteFile(filePath, data, 0644); err != nil {
+		return fmt.Errorf("无法写入文件：%s\n错误原因：%v", filePath, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// transactionFileName 是每次生成后写入目标目录的事务日志文件名
+const transactionFileName = ".dirtree-tx.json"
+
+// OperationType 标识一次磁盘写入属于哪种操作，决定撤销时如何校验与回滚
+type OperationType string
+
+const (
+	OpCreateDir   OperationType = "create-dir"   // 显式创建的目录（含带 type 的目录节点）
+	OpCreateFile  OperationType = "create-file"  // 创建的文件
+	OpSymlink     OperationType = "symlink"      // 创建的软链接
+	OpMkdirParent OperationType = "mkdir-parent" // 生成开始前补建的目标根路径
+)
+
+// Operation 记录一次成功的磁盘写入，连同写入当时的状态，供生成完成后的撤销校验使用
+type Operation struct {
+	Type    OperationType `json:"type"`
+	Path    string        `json:"path"`
+	ModTime time.Time     `json:"mod_time"`
+	Size    int64         `json:"size"`
+}
+
+// recordOperation 在一次写入成功后读取其最新状态并追加到操作列表；ops 为 nil 时（例如试运行）直接跳过
+func recordOperation(ops *[]Operation, opType OperationType, path string) {
+	if ops == nil {
+		return
+	}
+	op := Operation{Type: opType, Path: path}
+	if info, err := os.Lstat(path); err == nil {
+		op.ModTime = info.ModTime()
+		op.Size = info.Size()
+	}
+	*ops = append(*ops, op)
+}
+
+// writeTransactionLog 把本次生成产生的操作序列写入目标目录下的事务日志文件
+func writeTransactionLog(basePath string, ops []Operation) error {
+	if len(ops) == 0 {
+		return nil
+	}
+	data, err := json.MarshalIndent(ops, "", "  ")
+	if err != nil {
+		return fmt.Errorf("事务日志序列化失败：%v", err)
+	}
+	txPath := filepath.Join(basePath, transactionFileName)
+	if err := os.WriteFile(txPath, data, 0644); err != nil {
+		return fmt.Errorf("无法写入事务日志：%s\n错误原因：%v", txPath, err)
+	}
+	return nil
+}
+
+// loadTransactionLog 读取目标目录下的事务日志，用于撤销上一次生成
+func loadTransactionLog(basePath string) ([]Operation, error) {
+	txPath := filepath.Join(basePath, transactionFileName)
+	data, err := os.ReadFile(txPath)
+	if err != nil {
+		return nil, fmt.Errorf("未找到可撤销的事务日志：%s\n错误原因：%v", txPath, err)
+	}
+	var ops []Operation
+	if err := json.Unmarshal(data, &ops); err != nil {
+		return nil, fmt.Errorf("事务日志解析失败：%s\n错误原因：%v", txPath, err)
+	}
+	return ops, nil
+}
+
+// checkUndoSafety 判断单个操作当前是否仍可安全撤销：
+// 目录必须仍为空，文件/软链接的修改时间与大小必须与创建时一致，路径必须仍然存在。
+func checkUndoSafety(op Operation) (reason string, ok bool) {
+	info, err := os.Lstat(op.Path)
+	if err != nil {
+		return "路径已不存在，无需撤销", false
+	}
+
+	switch op.Type {
+	case OpCreateDir, OpMkdirParent:
+		if !info.IsDir() {
+			return "路径已不再是目录", false
+		}
+		entries, err := os.ReadDir(op.Path)
+		if err != nil {
+			return fmt.Sprintf("无法读取目录内容：%v", err), false
+		}
+		if len(entries) > 0 {
+			return "目录不再为空，可能包含生成后新增的内容", false
+		}
+	default:
+		if !info.ModTime().Equal(op.ModTime) || info.Size() != op.Size {
+			return "内容自生成后已被修改", false
+		}
+	}
+	return "", true
+}
+
+// previewUndo 按 LIFO 顺序检查每个操作是否可以安全撤销，分别返回可删除的路径与被跳过的路径（附原因）
+func previewUndo(ops []Operation) (toDelete []string, skipped []string) {
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		if reason, ok := checkUndoSafety(op); ok {
+			toDelete = append(toDelete, op.Path)
+		} else {
+			skipped = append(skipped, fmt.Sprintf("%s（%s）", op.Path, reason))
+		}
+	}
+	return toDelete, skipped
+}
+
+// undoTransaction 按 LIFO 顺序撤销一批操作：只删除仍满足安全检查的路径，返回撤销过程的日志；
+// 完成后清理事务日志文件本身，避免同一份日志被重复执行撤销。
+func undoTransaction(basePath string, ops []Operation) []string {
+	var logs []string
+	for i := len(ops) - 1; i >= 0; i-- {
+		op := ops[i]
+		reason, ok := checkUndoSafety(op)
+		if !ok {
+			logs = append(logs, fmt.Sprintf("跳过：%s（%s）\n", op.Path, reason))
+			continue
+		}
+		if err := os.Remove(op.Path); err != nil {
+			logs = append(logs, fmt.Sprintf("删除失败：%s\n原因：%v\n", op.Path, err))
+			continue
+		}
+		logs = append(logs, fmt.Sprintf("✓ 已撤销：%s\n", op.Path))
+	}
+
+	txPath := filepath.Join(basePath, transactionFileName)
+	if err := os.Remove(txPath); err != nil {
+		logs = append(logs, fmt.Sprintf("警告：无法清理事务日志：%s\n原因：%v\n", txPath, err))
+	}
+	return logs
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckUndoSafetyMissingPath(t *testing.T) {
+	op := Operation{Type: OpCreateDir, Path: filepath.Join(t.TempDir(), "missing")}
+	if _, ok := checkUndoSafety(op); ok {
+		t.Fatal("路径不存在时不应判定为可安全撤销")
+	}
+}
+
+func TestCheckUndoSafetyEmptyDirOK(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "empty")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	op := Operation{Type: OpCreateDir, Path: dir}
+	if _, ok := checkUndoSafety(op); !ok {
+		t.Fatal("空目录应当可以安全撤销")
+	}
+}
+
+func TestCheckUndoSafetyNonEmptyDirRejected(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nonempty")
+	if err := os.Mkdir(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "extra.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	op := Operation{Type: OpCreateDir, Path: dir}
+	if _, ok := checkUndoSafety(op); ok {
+		t.Fatal("目录内有生成后新增的内容时不应判定为可安全撤销")
+	}
+}
+
+func TestCheckUndoSafetyFileUnmodified(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := Operation{Type: OpCreateFile, Path: file, ModTime: info.ModTime(), Size: info.Size()}
+	if _, ok := checkUndoSafety(op); !ok {
+		t.Fatal("内容未发生变化的文件应当可以安全撤销")
+	}
+}
+
+func TestCheckUndoSafetyFileModifiedRejected(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(file, []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	op := Operation{Type: OpCreateFile, Path: file, ModTime: time.Now().Add(-time.Hour), Size: 999}
+	if _, ok := checkUndoSafety(op); ok {
+		t.Fatal("修改时间/大小与记录不一致时不应判定为可安全撤销")
+	}
+}
+
+func TestPreviewUndoSkipsNonEmptyDir(t *testing.T) {
+	base := t.TempDir()
+	dirtyDir := filepath.Join(base, "dirty")
+	cleanDir := filepath.Join(base, "clean")
+	if err := os.Mkdir(dirtyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(cleanDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dirtyDir, "extra.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ops := []Operation{
+		{Type: OpCreateDir, Path: cleanDir},
+		{Type: OpCreateDir, Path: dirtyDir},
+	}
+	toDelete, skipped := previewUndo(ops)
+
+	if len(toDelete) != 1 || toDelete[0] != cleanDir {
+		t.Fatalf("应当只有 clean 目录可删除，got %v", toDelete)
+	}
+	if len(skipped) != 1 {
+		t.Fatalf("应当有一条被跳过的记录，got %v", skipped)
+	}
+}
@@ -0,0 +1,390 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// pathSeparator 用于在树节点 UID 中串联从根到当前节点的各级名称
+const pathSeparator = "\x1f"
+
+// splitUID 把 UID 拆分为从根到当前节点的名称路径；根节点的 UID 为空字符串
+func splitUID(uid string) []string {
+	if uid == "" {
+		return nil
+	}
+	return strings.Split(uid, pathSeparator)
+}
+
+// joinUID 由父节点 UID 与子节点名称拼出子节点的 UID
+func joinUID(parentUID, name string) string {
+	if parentUID == "" {
+		return name
+	}
+	return parentUID + pathSeparator + name
+}
+
+// childrenOf 返回 parent[key] 这个节点可供继续展开、写入的子节点 map；文件、软链接等叶子节点返回 false。
+// 节点值为 nil（JSON/YAML 的 null，也是"新增子级/同级"自己写入的占位值）或其他无法展开的旧版格式时，
+// 会原地把它升级为一个空的、可继续增长的 map 并写回 parent，而不是直接拒绝——否则在一个刚创建出来的
+// 占位节点上"新增子级"会无声地失败。
+func childrenOf(parent map[string]interface{}, key string) (map[string]interface{}, bool) {
+	raw, exists := parent[key]
+	if !exists {
+		return nil, false
+	}
+	if node, ok := parseTypedNode(raw); ok {
+		if node.Type != nodeTypeDir {
+			return nil, false
+		}
+		if node.Children == nil {
+			children := map[string]interface{}{}
+			raw.(map[string]interface{})["children"] = children
+			return children, true
+		}
+		return node.Children, true
+	}
+	if m, ok := raw.(map[string]interface{}); ok && m != nil {
+		return m, true
+	}
+	children := map[string]interface{}{}
+	parent[key] = children
+	return children, true
+}
+
+// lookupContainer 从根结构出发，沿 UID 路径定位到该层节点所在的 map（UID 本身指向一个分支节点）
+func lookupContainer(root map[string]interface{}, uid string) (map[string]interface{}, bool) {
+	current := root
+	for _, name := range splitUID(uid) {
+		children, hasChildren := childrenOf(current, name)
+		if !hasChildren {
+			return nil, false
+		}
+		current = children
+	}
+	return current, true
+}
+
+// resolveUID 从根结构出发，沿 UID 路径定位到该节点所在的父 map 及其键名
+func resolveUID(root map[string]interface{}, uid string) (parent map[string]interface{}, key string, ok bool) {
+	parts := splitUID(uid)
+	if len(parts) == 0 {
+		return nil, "", false
+	}
+	parentUID := strings.Join(parts[:len(parts)-1], pathSeparator)
+	parent, ok = lookupContainer(root, parentUID)
+	if !ok {
+		return nil, "", false
+	}
+	key = parts[len(parts)-1]
+	if _, exists := parent[key]; !exists {
+		return nil, "", false
+	}
+	return parent, key, true
+}
+
+// buildTreeDataSource 把 map[string]interface{} 结构适配为 widget.NewTree 所需的数据源回调
+func buildTreeDataSource(root map[string]interface{}, onSecondary func(uid string, pos fyne.Position)) (
+	childUIDs func(fyne.TreeNodeID) []fyne.TreeNodeID,
+	isBranch func(fyne.TreeNodeID) bool,
+	create func(bool) fyne.CanvasObject,
+	update func(fyne.TreeNodeID, bool, fyne.CanvasObject),
+) {
+	childUIDs = func(uid fyne.TreeNodeID) []fyne.TreeNodeID {
+		m, ok := lookupContainer(root, string(uid))
+		if !ok {
+			return nil
+		}
+		uids := make([]fyne.TreeNodeID, 0, len(m))
+		for name := range m {
+			uids = append(uids, fyne.TreeNodeID(joinUID(string(uid), name)))
+		}
+		return uids
+	}
+
+	isBranch = func(uid fyne.TreeNodeID) bool {
+		parts := splitUID(string(uid))
+		if len(parts) == 0 {
+			return true
+		}
+		parent, ok := lookupContainer(root, strings.Join(parts[:len(parts)-1], pathSeparator))
+		if !ok {
+			return false
+		}
+		_, hasChildren := childrenOf(parent, parts[len(parts)-1])
+		return hasChildren
+	}
+
+	create = func(branch bool) fyne.CanvasObject {
+		return newTreeNodeLabel("", onSecondary)
+	}
+
+	update = func(uid fyne.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+		label, ok := obj.(*treeNodeLabel)
+		if !ok {
+			return
+		}
+		parts := splitUID(string(uid))
+		name := ""
+		if len(parts) > 0 {
+			name = parts[len(parts)-1]
+		}
+		label.uid = string(uid)
+		parent, _ := lookupContainer(root, strings.Join(parts[:max(len(parts)-1, 0)], pathSeparator))
+		if node, typed := parseTypedNode(parent[name]); typed {
+			switch node.Type {
+			case nodeTypeFile:
+				name += "  [文件]"
+			case nodeTypeSymlink:
+				name += "  [软链接]"
+			}
+			if node.Skip {
+				name += "  (已跳过)"
+			}
+			if node.PrefixOverride != "" {
+				name += fmt.Sprintf("  前缀=%s", node.PrefixOverride)
+			}
+		}
+		label.SetText(name)
+	}
+
+	return
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// treeNodeLabel 是 widget.Label 的轻量封装，用于在树节点上捕获右键（次要点击）事件
+type treeNodeLabel struct {
+	widget.Label
+	uid       string
+	secondary func(uid string, pos fyne.Position)
+}
+
+func newTreeNodeLabel(text string, secondary func(uid string, pos fyne.Position)) *treeNodeLabel {
+	l := &treeNodeLabel{secondary: secondary}
+	l.Text = text
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+// TappedSecondary 实现 fyne.SecondaryTappable 的右键回调，用于弹出节点编辑菜单
+func (l *treeNodeLabel) TappedSecondary(ev *fyne.PointEvent) {
+	if l.secondary != nil {
+		l.secondary(l.uid, ev.AbsolutePosition)
+	}
+}
+
+var _ fyne.Tappable = (*treeNodeLabel)(nil)
+var _ fyne.SecondaryTappable = (*treeNodeLabel)(nil)
+
+// Tapped 留空：树节点的单击展开/折叠已经由 widget.Tree 本身处理
+func (l *treeNodeLabel) Tapped(*fyne.PointEvent) {}
+
+// showNodeEditMenu 弹出节点编辑菜单：重命名、删除、新增同级/子级、切换跳过、设置局部前缀
+func showNodeEditMenu(win fyne.Window, root map[string]interface{}, uid string, pos fyne.Position, onChange func()) {
+	if uid == "" {
+		return
+	}
+	parent, key, ok := resolveUID(root, uid)
+	if !ok {
+		return
+	}
+
+	items := []*fyne.MenuItem{
+		fyne.NewMenuItem("重命名", func() {
+			entry := widget.NewEntry()
+			entry.SetText(key)
+			dialog.ShowForm("重命名节点", "确定", "取消", []*widget.FormItem{
+				widget.NewFormItem("新名称", entry),
+			}, func(confirmed bool) {
+				if !confirmed || entry.Text == "" || entry.Text == key {
+					return
+				}
+				if _, collide := parent[entry.Text]; collide {
+					dialog.ShowInformation("无法重命名", fmt.Sprintf("已存在同名节点 \"%s\"", entry.Text), win)
+					return
+				}
+				parent[entry.Text] = parent[key]
+				delete(parent, key)
+				onChange()
+			}, win)
+		}),
+		fyne.NewMenuItem("删除", func() {
+			dialog.ShowConfirm("删除节点", fmt.Sprintf("确定要删除 \"%s\" 吗？", key), func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				delete(parent, key)
+				onChange()
+			}, win)
+		}),
+		fyne.NewMenuItem("新增子级", func() {
+			entry := widget.NewEntry()
+			dialog.ShowForm("新增子级", "确定", "取消", []*widget.FormItem{
+				widget.NewFormItem("名称", entry),
+			}, func(confirmed bool) {
+				if !confirmed || entry.Text == "" {
+					return
+				}
+				children, hasChildren := childrenOf(parent, key)
+				if !hasChildren {
+					return
+				}
+				if _, collide := children[entry.Text]; collide {
+					dialog.ShowInformation("无法新增", fmt.Sprintf("\"%s\" 下已存在同名节点 \"%s\"", key, entry.Text), win)
+					return
+				}
+				children[entry.Text] = nil
+				onChange()
+			}, win)
+		}),
+		fyne.NewMenuItem("新增同级", func() {
+			entry := widget.NewEntry()
+			dialog.ShowForm("新增同级", "确定", "取消", []*widget.FormItem{
+				widget.NewFormItem("名称", entry),
+			}, func(confirmed bool) {
+				if !confirmed || entry.Text == "" {
+					return
+				}
+				if _, collide := parent[entry.Text]; collide {
+					dialog.ShowInformation("无法新增", fmt.Sprintf("已存在同名节点 \"%s\"", entry.Text), win)
+					return
+				}
+				parent[entry.Text] = nil
+				onChange()
+			}, win)
+		}),
+		fyne.NewMenuItem("切换跳过", func() {
+			toggleNodeSkip(parent, key)
+			onChange()
+		}),
+		fyne.NewMenuItem("设置局部前缀", func() {
+			entry := widget.NewEntry()
+			entry.SetPlaceHolder("例如：C_")
+			dialog.ShowForm("设置局部前缀", "确定", "取消", []*widget.FormItem{
+				widget.NewFormItem("前缀", entry),
+			}, func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				setNodePrefixOverride(parent, key, entry.Text)
+				onChange()
+			}, win)
+		}),
+	}
+
+	widget.ShowPopUpMenuAtPosition(fyne.NewMenu("", items...), win.Canvas(), pos)
+}
+
+// toggleNodeSkip 翻转节点的 skip 状态；若节点还是旧版纯 map 格式，会就地升级为带类型的目录节点以承载该元数据
+func toggleNodeSkip(parent map[string]interface{}, key string) {
+	node, typed := parseTypedNode(parent[key])
+	if !typed {
+		children, _ := childrenOf(parent, key)
+		parent[key] = map[string]interface{}{
+			"type":     nodeTypeDir,
+			"skip":     true,
+			"children": children,
+		}
+		return
+	}
+	raw := parent[key].(map[string]interface{})
+	raw["skip"] = !node.Skip
+}
+
+// setNodePrefixOverride 设置节点的局部前缀覆盖，必要时同样会就地升级为带类型的目录节点
+func setNodePrefixOverride(parent map[string]interface{}, key, prefixOverride string) {
+	_, typed := parseTypedNode(parent[key])
+	if !typed {
+		children, _ := childrenOf(parent, key)
+		parent[key] = map[string]interface{}{
+			"type":     nodeTypeDir,
+			"prefix":   prefixOverride,
+			"children": children,
+		}
+		return
+	}
+	raw := parent[key].(map[string]interface{})
+	raw["prefix"] = prefixOverride
+}
+
+// dryRunPreview 模拟一次生成过程，仅返回将要执行的操作描述，并对已存在的路径标注冲突，不写入磁盘
+func dryRunPreview(basePath string, structure map[string]interface{}, ctx genContext) []string {
+	var logs []string
+	for index, name := range sortedKeys(structure) {
+		raw := structure[name]
+		if name == "" {
+			continue
+		}
+
+		node, typed := parseTypedNode(raw)
+		if typed && node.Skip {
+			logs = append(logs, fmt.Sprintf("跳过（已标记跳过）：%s\n", name))
+			continue
+		}
+
+		finalName := name
+		switch {
+		case typed && node.PrefixOverride != "":
+			finalName = node.PrefixOverride + name
+		case len(ctx.Rules) > 0:
+			nameCtx := nameContext{
+				Name:       name,
+				Depth:      ctx.Depth + 1,
+				Path:       joinRelPath(ctx.RelPath, name),
+				Index:      index,
+				ParentName: ctx.ParentName,
+			}
+			if rendered, err := applyNamingRules(ctx.Rules, nameCtx); err == nil {
+				finalName = rendered
+			}
+		}
+
+		fullPath := filepath.Join(basePath, finalName)
+		_, statErr := os.Stat(fullPath)
+		exists := statErr == nil
+
+		switch {
+		case typed && node.Type == nodeTypeFile:
+			if exists {
+				logs = append(logs, fmt.Sprintf("冲突：文件已存在，将被覆盖：%s\n", fullPath))
+			} else {
+				logs = append(logs, fmt.Sprintf("将创建文件：%s\n", fullPath))
+			}
+		case typed && node.Type == nodeTypeSymlink:
+			// os.Symlink 在路径已存在时会报错——哪怕是悬空的软链接——所以这里必须用
+			// Lstat 而非 Stat，否则悬空软链接会被 Stat 判定为"不存在"从而漏报冲突
+			_, lstatErr := os.Lstat(fullPath)
+			exists = lstatErr == nil
+			if exists {
+				logs = append(logs, fmt.Sprintf("冲突：路径已存在：%s\n", fullPath))
+			} else {
+				logs = append(logs, fmt.Sprintf("将创建软链接：%s -> %s\n", fullPath, node.Target))
+			}
+		case typed && node.Type != nodeTypeDir:
+			// 未知/拼写错误的 type 取值：与 createDirs 保持一致，明确跳过而不是当作目录预览
+			logs = append(logs, fmt.Sprintf("跳过：未知的 type 取值 \"%s\"，节点 \"%s\" 已忽略\n", node.Type, name))
+		default:
+			if exists {
+				logs = append(logs, fmt.Sprintf("目录已存在（将合并）：%s\n", fullPath))
+			} else {
+				logs = append(logs, fmt.Sprintf("将创建目录：%s\n", fullPath))
+			}
+			if children, ok := childrenOf(structure, name); ok && len(children) > 0 {
+				logs = append(logs, dryRunPreview(fullPath, children, ctx.child(name))...)
+			}
+		}
+	}
+	return logs
+}